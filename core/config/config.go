@@ -0,0 +1,89 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package config provides the typed, watchable configuration tree
+// backing ServiceHost.Config(). Components read values with Get, and
+// may react to live changes with Watch; ServiceHost.Reload is what
+// actually pulls in new values and triggers those watchers.
+package config
+
+import "encoding/json"
+
+// Value is a single node of the configuration tree, as returned by
+// Get. The zero Value has no value; Populate leaves out untouched in
+// that case.
+type Value struct {
+	raw interface{}
+}
+
+// NewValue wraps raw as a Value. Providers use this to implement Get;
+// most callers only ever receive a Value, they do not construct one.
+func NewValue(raw interface{}) Value {
+	return Value{raw: raw}
+}
+
+// HasValue reports whether the key this Value was read from was
+// actually present in the configuration tree.
+func (v Value) HasValue() bool {
+	return v.raw != nil
+}
+
+// Populate decodes the value into out, which should be a pointer. A
+// Value with no underlying data leaves out unchanged.
+func (v Value) Populate(out interface{}) error {
+	if v.raw == nil {
+		return nil
+	}
+
+	// The tree is built from plain map[string]interface{}/[]interface{}
+	// nodes regardless of source format (YAML, JSON, flags, ...), so a
+	// JSON round-trip is a convenient, dependency-free way to decode it
+	// into a caller's typed struct.
+	data, err := json.Marshal(v.raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// CancelFunc stops a subscription registered with
+// ConfigurationProvider.Watch.
+type CancelFunc func()
+
+// ConfigurationProvider is the configuration surface exposed through
+// ServiceHost.Config(). It is intentionally narrow: read a value by
+// key, subscribe to changes under a key, and reload a named scope.
+type ConfigurationProvider interface {
+	// Get reads the value at key, a dot-separated path into the
+	// configuration tree (e.g. "tracing.endpoint").
+	Get(key string) Value
+
+	// Watch registers fn to run whenever a Reload changes a key at or
+	// under path. fn receives the freshly-read Value for path itself,
+	// not the specific sub-key that changed. Call the returned
+	// CancelFunc to unsubscribe.
+	Watch(path string, fn func(newValue Value)) (CancelFunc, error)
+
+	// Reload re-reads the named scope from its registered source (see
+	// Provider.RegisterSource) and notifies any Watch subscribers whose
+	// path intersects what changed. It returns an error if no source is
+	// registered for scope, or if loading fails.
+	Reload(scope string) error
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCoalesceWindow bounds how long Provider waits after the last
+// change in a burst before it fans changes out to watchers, so a
+// Reload touching many keys (or several Reloads arriving close
+// together) notifies each watcher once rather than once per key.
+const defaultCoalesceWindow = 200 * time.Millisecond
+
+// Source loads the latest raw tree for a scope, e.g. by re-reading a
+// file or re-resolving a remote config source. It is registered with
+// Provider.RegisterSource and invoked by Reload.
+type Source func() (map[string]interface{}, error)
+
+type watcher struct {
+	id   uint64
+	path string
+	fn   func(Value)
+}
+
+// Provider is the default, in-memory ConfigurationProvider: a single
+// versioned snapshot of the configuration tree, reloaded scope by
+// scope. Use NewProvider to create one.
+type Provider struct {
+	mu      sync.RWMutex
+	version uint64
+	root    map[string]interface{}
+	sources map[string]Source
+
+	watchMu  sync.Mutex
+	nextID   uint64
+	watchers map[string][]*watcher
+
+	coalesceWindow time.Duration
+	pendingMu      sync.Mutex
+	pendingPaths   map[string]struct{}
+	flushTimer     *time.Timer
+}
+
+// NewProvider creates a Provider seeded with root, e.g. the tree
+// decoded from a service's initial config file.
+func NewProvider(root map[string]interface{}) *Provider {
+	if root == nil {
+		root = make(map[string]interface{})
+	}
+	return &Provider{
+		root:           root,
+		sources:        make(map[string]Source),
+		watchers:       make(map[string][]*watcher),
+		coalesceWindow: defaultCoalesceWindow,
+		pendingPaths:   make(map[string]struct{}),
+	}
+}
+
+// RegisterSource wires src as the loader Reload(scope) uses to fetch a
+// fresh subtree. The subtree src returns replaces root[scope] wholesale
+// on every Reload.
+func (p *Provider) RegisterSource(scope string, src Source) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sources[scope] = src
+}
+
+// Version returns the number of successful Reloads so far, whether or
+// not they changed the tree. Mainly useful for tests and diagnostics.
+func (p *Provider) Version() uint64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.version
+}
+
+// Get implements ConfigurationProvider.
+func (p *Provider) Get(key string) Value {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return NewValue(lookup(p.root, key))
+}
+
+// Watch implements ConfigurationProvider.
+func (p *Provider) Watch(path string, fn func(Value)) (CancelFunc, error) {
+	if path == "" {
+		return nil, fmt.Errorf("config: watch path must not be empty")
+	}
+
+	p.watchMu.Lock()
+	id := p.nextID
+	p.nextID++
+	p.watchers[path] = append(p.watchers[path], &watcher{id: id, path: path, fn: fn})
+	p.watchMu.Unlock()
+
+	return func() {
+		p.watchMu.Lock()
+		defer p.watchMu.Unlock()
+		ws := p.watchers[path]
+		for i, w := range ws {
+			if w.id == id {
+				p.watchers[path] = append(ws[:i:i], ws[i+1:]...)
+				return
+			}
+		}
+	}, nil
+}
+
+// Reload implements ConfigurationProvider.
+func (p *Provider) Reload(scope string) error {
+	p.mu.RLock()
+	src := p.sources[scope]
+	p.mu.RUnlock()
+	if src == nil {
+		return fmt.Errorf("config: no reload source registered for scope %q", scope)
+	}
+
+	fresh, err := src()
+	if err != nil {
+		return fmt.Errorf("config: reloading scope %q: %w", scope, err)
+	}
+
+	p.mu.Lock()
+	old := p.root
+	next := shallowCopy(old)
+	next[scope] = toInterfaceMap(fresh)
+	p.root = next
+	p.version++
+	p.mu.Unlock()
+
+	changed := diffPointers("", old, next)
+	p.scheduleNotify(changed)
+	return nil
+}
+
+func (p *Provider) scheduleNotify(changed []string) {
+	if len(changed) == 0 {
+		return
+	}
+
+	p.pendingMu.Lock()
+	for _, c := range changed {
+		p.pendingPaths[c] = struct{}{}
+	}
+	if p.flushTimer != nil {
+		p.flushTimer.Stop()
+	}
+	p.flushTimer = time.AfterFunc(p.coalesceWindow, p.flush)
+	p.pendingMu.Unlock()
+}
+
+func (p *Provider) flush() {
+	p.pendingMu.Lock()
+	pending := p.pendingPaths
+	p.pendingPaths = make(map[string]struct{})
+	p.flushTimer = nil
+	p.pendingMu.Unlock()
+
+	p.watchMu.Lock()
+	var toNotify []*watcher
+	for watchPath, ws := range p.watchers {
+		for changedPointer := range pending {
+			if pathIntersects(watchPath, changedPointer) {
+				toNotify = append(toNotify, ws...)
+				break
+			}
+		}
+	}
+	p.watchMu.Unlock()
+
+	for _, w := range toNotify {
+		w.fn(p.Get(w.path))
+	}
+}
+
+func lookup(root map[string]interface{}, key string) interface{} {
+	var cur interface{} = root
+	for _, part := range strings.Split(key, ".") {
+		if part == "" {
+			continue
+		}
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur, ok = m[part]
+		if !ok {
+			return nil
+		}
+	}
+	return cur
+}
+
+func shallowCopy(m map[string]interface{}) map[string]interface{} {
+	cp := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		cp[k] = v
+	}
+	return cp
+}
+
+func toInterfaceMap(m map[string]interface{}) interface{} {
+	if m == nil {
+		return nil
+	}
+	return m
+}
+
+// diffPointers walks old and next in lockstep and returns the JSON
+// Pointer (RFC 6901) path of every key that was added, removed, or
+// changed, prefixed with the path taken to reach them.
+func diffPointers(prefix string, old, next interface{}) []string {
+	oldMap, oldIsMap := old.(map[string]interface{})
+	nextMap, nextIsMap := next.(map[string]interface{})
+	if !oldIsMap || !nextIsMap {
+		if reflect.DeepEqual(old, next) {
+			return nil
+		}
+		return []string{prefix}
+	}
+
+	var paths []string
+	seen := make(map[string]struct{}, len(nextMap))
+	for k, nv := range nextMap {
+		seen[k] = struct{}{}
+		p := prefix + "/" + k
+		ov, existed := oldMap[k]
+		if !existed {
+			paths = append(paths, p)
+			continue
+		}
+		paths = append(paths, diffPointers(p, ov, nv)...)
+	}
+	for k := range oldMap {
+		if _, ok := seen[k]; !ok {
+			paths = append(paths, prefix+"/"+k)
+		}
+	}
+	return paths
+}
+
+// pathIntersects reports whether a dot-separated Watch path and a JSON
+// Pointer changed path refer to overlapping parts of the tree, i.e.
+// one is a prefix of the other at a segment boundary.
+func pathIntersects(watchPath, changedPointer string) bool {
+	w := "/" + strings.Trim(strings.ReplaceAll(watchPath, ".", "/"), "/")
+	c := "/" + strings.Trim(changedPointer, "/")
+	return w == c || strings.HasPrefix(c, w+"/") || strings.HasPrefix(w, c+"/")
+}
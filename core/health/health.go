@@ -0,0 +1,281 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package health provides an aggregated healthcheck subsystem that
+// components register named checks with, and that ServiceHost exposes
+// as a single HealthReport.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/uber-go/tally"
+)
+
+// Status is the outcome of a single check, or the aggregate outcome of
+// a whole HealthReport.
+type Status int
+
+const (
+	// Healthy means the check passed.
+	Healthy Status = iota
+	// Degraded means the check failed but was registered as non-fatal.
+	Degraded
+	// Unhealthy means a fatal check failed.
+	Unhealthy
+)
+
+func (s Status) String() string {
+	switch s {
+	case Healthy:
+		return "healthy"
+	case Degraded:
+		return "degraded"
+	case Unhealthy:
+		return "unhealthy"
+	default:
+		return "unknown"
+	}
+}
+
+// CheckResult is the most recent outcome of a single registered check.
+type CheckResult struct {
+	Name      string    `json:"name"`
+	Status    Status    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// HealthReport is the aggregated result of running (or reading the
+// cached result of) every registered check.
+type HealthReport struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// HealthOption configures a registered check.
+type HealthOption func(*checkConfig)
+
+type checkConfig struct {
+	interval time.Duration
+	timeout  time.Duration
+	fatal    bool
+	cacheFor time.Duration
+}
+
+// WithInterval runs the check in the background on the given interval
+// instead of only when Healthcheck is called; Healthcheck then serves
+// the most recent background result for it.
+func WithInterval(d time.Duration) HealthOption {
+	return func(c *checkConfig) { c.interval = d }
+}
+
+// WithTimeout bounds how long a single run of the check may take. The
+// check is reported Unhealthy/Degraded (depending on Fatal) if it
+// exceeds this.
+func WithTimeout(d time.Duration) HealthOption {
+	return func(c *checkConfig) { c.timeout = d }
+}
+
+// Fatal marks the check as critical: its failure makes the aggregate
+// report Unhealthy rather than Degraded.
+func Fatal() HealthOption {
+	return func(c *checkConfig) { c.fatal = true }
+}
+
+// CachedFor serves the last result for up to d before running the
+// check live again on the next Healthcheck call. Without this option
+// every Healthcheck call runs the check live (unless WithInterval is
+// also set, in which case the background result is always served).
+func CachedFor(d time.Duration) HealthOption {
+	return func(c *checkConfig) { c.cacheFor = d }
+}
+
+type check struct {
+	name   string
+	fn     func(ctx context.Context) error
+	config checkConfig
+	gauge  tally.Gauge
+
+	mu        sync.Mutex
+	lastRun   time.Time
+	lastErr   error
+	lastState Status
+}
+
+// Registry aggregates named checks and reports on them as a whole. Use
+// NewRegistry to create one; a ServiceHost exposes its Registry through
+// Health().
+type Registry struct {
+	scope tally.Scope
+
+	mu      sync.Mutex
+	checks  map[string]*check
+	closers map[string]func()
+}
+
+// NewRegistry creates an empty Registry that publishes per-check gauges
+// into scope.
+func NewRegistry(scope tally.Scope) *Registry {
+	return &Registry{
+		scope:   scope,
+		checks:  make(map[string]*check),
+		closers: make(map[string]func()),
+	}
+}
+
+// Register adds a named check. Registering under a name that is
+// already registered replaces it and stops its previous background
+// goroutine, if any.
+func (r *Registry) Register(name string, fn func(ctx context.Context) error, opts ...HealthOption) {
+	cfg := checkConfig{timeout: 5 * time.Second}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	c := &check{
+		name:   name,
+		fn:     fn,
+		config: cfg,
+		gauge:  r.scope.Tagged(map[string]string{"check": name}).Gauge("health"),
+	}
+
+	r.mu.Lock()
+	r.checks[name] = c
+	if cancel, ok := r.closers[name]; ok {
+		cancel()
+		delete(r.closers, name)
+	}
+	r.mu.Unlock()
+
+	if cfg.interval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		r.mu.Lock()
+		r.closers[name] = cancel
+		r.mu.Unlock()
+		go r.runInBackground(ctx, c)
+	}
+}
+
+func (r *Registry) runInBackground(ctx context.Context, c *check) {
+	ticker := time.NewTicker(c.config.interval)
+	defer ticker.Stop()
+
+	r.run(ctx, c)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.run(ctx, c)
+		}
+	}
+}
+
+func (r *Registry) run(ctx context.Context, c *check) CheckResult {
+	var err error
+	if c.config.timeout > 0 {
+		runCtx, cancel := context.WithTimeout(ctx, c.config.timeout)
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() { errCh <- c.fn(runCtx) }()
+		select {
+		case err = <-errCh:
+		case <-runCtx.Done():
+			err = runCtx.Err()
+		}
+	} else {
+		err = c.fn(ctx)
+	}
+
+	status := Healthy
+	if err != nil {
+		status = Degraded
+		if c.config.fatal {
+			status = Unhealthy
+		}
+	}
+
+	c.mu.Lock()
+	c.lastRun = time.Now()
+	c.lastErr = err
+	c.lastState = status
+	c.mu.Unlock()
+
+	c.gauge.Update(float64(status))
+
+	res := CheckResult{Name: c.name, Status: status, CheckedAt: c.lastRun}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// Healthcheck runs (or reads the cached result of) every registered
+// check and returns the aggregated report.
+func (r *Registry) Healthcheck(ctx context.Context) HealthReport {
+	r.mu.Lock()
+	checks := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		checks = append(checks, c)
+	}
+	r.mu.Unlock()
+
+	report := HealthReport{Status: Healthy, Checks: make(map[string]CheckResult, len(checks))}
+	for _, c := range checks {
+		res := r.resultFor(ctx, c)
+		report.Checks[c.name] = res
+		if res.Status == Unhealthy {
+			report.Status = Unhealthy
+		} else if res.Status == Degraded && report.Status == Healthy {
+			report.Status = Degraded
+		}
+	}
+	return report
+}
+
+func (r *Registry) resultFor(ctx context.Context, c *check) CheckResult {
+	c.mu.Lock()
+	haveCached := !c.lastRun.IsZero()
+	fresh := haveCached && (c.config.interval > 0 || (c.config.cacheFor > 0 && time.Since(c.lastRun) < c.config.cacheFor))
+	name, lastRun, lastErr, lastState := c.name, c.lastRun, c.lastErr, c.lastState
+	c.mu.Unlock()
+
+	if fresh {
+		res := CheckResult{Name: name, Status: lastState, CheckedAt: lastRun}
+		if lastErr != nil {
+			res.Error = lastErr.Error()
+		}
+		return res
+	}
+	return r.run(ctx, c)
+}
+
+// Close stops every background check goroutine started by Register.
+func (r *Registry) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cancel := range r.closers {
+		cancel()
+	}
+	r.closers = make(map[string]func())
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package health
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/uber-go/tally"
+)
+
+// TestRegisterStopsPreviousBackgroundCheck guards against re-Register
+// leaving the old check's background goroutine running forever: if it
+// leaked, runs would keep landing on the stale closure's counter after
+// the new one replaces it in r.checks.
+func TestRegisterStopsPreviousBackgroundCheck(t *testing.T) {
+	r := NewRegistry(tally.NoopScope)
+
+	var staleRuns int32
+	r.Register("check", func(context.Context) error {
+		atomic.AddInt32(&staleRuns, 1)
+		return nil
+	}, WithInterval(5*time.Millisecond))
+
+	// Let the stale check run at least once so we know its goroutine
+	// was actually live before we replace it.
+	time.Sleep(20 * time.Millisecond)
+
+	r.Register("check", func(context.Context) error {
+		return nil
+	}, WithInterval(5*time.Millisecond))
+
+	runsAtReplace := atomic.LoadInt32(&staleRuns)
+	time.Sleep(30 * time.Millisecond)
+
+	if got := atomic.LoadInt32(&staleRuns); got != runsAtReplace {
+		t.Errorf("stale check kept running after Register replaced it: %d runs before replace, %d after", runsAtReplace, got)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package httphealth renders a ServiceHost's HealthReport as JSON so it
+// can be wired into whatever HTTP transport the service is using.
+package httphealth
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/fx/core"
+	"go.uber.org/fx/core/health"
+)
+
+// NewHandler returns an http.Handler that runs sh.Healthcheck and
+// writes the report as JSON, with a 200 status for Healthy/Degraded and
+// 503 for Unhealthy.
+func NewHandler(sh core.ServiceHost) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := sh.Healthcheck(r.Context())
+
+		w.Header().Set("Content-Type", "application/json")
+		if report.Status == health.Unhealthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		// Best-effort: if encoding fails there is nothing more useful to
+		// do than leave the response as-is.
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
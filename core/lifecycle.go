@@ -0,0 +1,255 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HookOption configures a warmup or shutdown hook registered on a
+// Lifecycle.
+type HookOption func(*hookConfig)
+
+type hookConfig struct {
+	after    []string
+	before   []string
+	timeout  time.Duration
+	optional bool
+}
+
+// After orders this hook to run after the hook registered as other,
+// once other's own dependencies have been satisfied.
+func After(other string) HookOption {
+	return func(c *hookConfig) { c.after = append(c.after, other) }
+}
+
+// Before orders this hook to run before the hook registered as other.
+func Before(other string) HookOption {
+	return func(c *hookConfig) { c.before = append(c.before, other) }
+}
+
+// WithTimeout bounds how long this hook may run before it is treated
+// as failed.
+func WithTimeout(d time.Duration) HookOption {
+	return func(c *hookConfig) { c.timeout = d }
+}
+
+// Optional marks a warmup hook as non-required: its error is discarded,
+// so it neither aborts startup nor is returned by RunWarmups. Shutdown
+// hooks ignore this option.
+func Optional() HookOption {
+	return func(c *hookConfig) { c.optional = true }
+}
+
+type hook struct {
+	name   string
+	fn     func(ctx context.Context) error
+	config hookConfig
+}
+
+// Lifecycle collects the warmup and shutdown hooks registered by
+// components and runs them in dependency order. Create one with
+// NewLifecycle; a ServiceHost exposes its Lifecycle through Lifecycle().
+type Lifecycle struct {
+	mu        sync.Mutex
+	warmups   []*hook
+	shutdowns []*hook
+}
+
+// NewLifecycle creates an empty Lifecycle.
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{}
+}
+
+// OnWarmup registers fn to run after wiring but before the service
+// starts accepting traffic. Hooks with satisfied dependencies run
+// concurrently; see After, Before, WithTimeout and Optional.
+func (l *Lifecycle) OnWarmup(name string, fn func(ctx context.Context) error, opts ...HookOption) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warmups = append(l.warmups, newHook(name, fn, opts))
+}
+
+// OnShutdown registers fn to run when the service is draining. Hooks
+// run in reverse dependency order: a hook registered After another
+// runs before it during shutdown.
+func (l *Lifecycle) OnShutdown(name string, fn func(ctx context.Context) error, opts ...HookOption) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h := newHook(name, fn, opts)
+	h.config.optional = false
+	l.shutdowns = append(l.shutdowns, h)
+}
+
+func newHook(name string, fn func(ctx context.Context) error, opts []HookOption) *hook {
+	var cfg hookConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &hook{name: name, fn: fn, config: cfg}
+}
+
+// RunWarmups runs every registered warmup hook, in as much parallelism
+// as the After/Before dependency graph allows, and returns the first
+// error from a required (non-Optional) hook. It blocks until every
+// hook in the failing layer (and any already-started layer) completes.
+func (l *Lifecycle) RunWarmups(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := append([]*hook(nil), l.warmups...)
+	l.mu.Unlock()
+
+	layers, err := layerHooks(hooks)
+	if err != nil {
+		return err
+	}
+
+	for _, layer := range layers {
+		if err := runLayer(ctx, layer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunShutdowns runs every registered shutdown hook in reverse
+// dependency order and returns the last error encountered, running
+// every hook regardless of earlier failures so that shutdown always
+// makes progress.
+func (l *Lifecycle) RunShutdowns(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := append([]*hook(nil), l.shutdowns...)
+	l.mu.Unlock()
+
+	layers, err := layerHooks(hooks)
+	if err != nil {
+		return err
+	}
+
+	var last error
+	for i := len(layers) - 1; i >= 0; i-- {
+		if err := runLayer(ctx, layers[i]); err != nil {
+			last = err
+		}
+	}
+	return last
+}
+
+// layerHooks topologically sorts hooks by their After/Before
+// constraints into layers that can each run concurrently, using Kahn's
+// algorithm. It returns an error if the constraints form a cycle.
+func layerHooks(hooks []*hook) ([][]*hook, error) {
+	byName := make(map[string]*hook, len(hooks))
+	for _, h := range hooks {
+		byName[h.name] = h
+	}
+
+	// deps[h] = set of hook names that must run before h.
+	deps := make(map[string]map[string]struct{}, len(hooks))
+	for _, h := range hooks {
+		deps[h.name] = make(map[string]struct{})
+	}
+	for _, h := range hooks {
+		for _, other := range h.config.after {
+			if _, ok := byName[other]; ok {
+				deps[h.name][other] = struct{}{}
+			}
+		}
+		for _, other := range h.config.before {
+			if _, ok := byName[other]; ok {
+				deps[other][h.name] = struct{}{}
+			}
+		}
+	}
+
+	var layers [][]*hook
+	remaining := hooks
+	for len(remaining) > 0 {
+		var layer, rest []*hook
+		for _, h := range remaining {
+			if len(deps[h.name]) == 0 {
+				layer = append(layer, h)
+			} else {
+				rest = append(rest, h)
+			}
+		}
+		if len(layer) == 0 {
+			return nil, fmt.Errorf("core: dependency cycle among lifecycle hooks")
+		}
+
+		layerNames := make(map[string]struct{}, len(layer))
+		for _, h := range layer {
+			layerNames[h.name] = struct{}{}
+		}
+		// Only hooks still pending (rest) can have deps left to clear;
+		// hooks already placed in a layer must not be rescanned, or
+		// they would be rescheduled into every later layer once their
+		// own deps entry is gone.
+		for _, h := range rest {
+			for done := range layerNames {
+				delete(deps[h.name], done)
+			}
+		}
+
+		layers = append(layers, layer)
+		remaining = rest
+	}
+	return layers, nil
+}
+
+func runLayer(ctx context.Context, layer []*hook) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(layer))
+
+	for i, h := range layer {
+		wg.Add(1)
+		go func(i int, h *hook) {
+			defer wg.Done()
+			errs[i] = runHook(ctx, h)
+		}(i, h)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil && !layer[i].config.optional {
+			return fmt.Errorf("core: lifecycle hook %q failed: %w", layer[i].name, err)
+		}
+	}
+	return nil
+}
+
+func runHook(ctx context.Context, h *hook) error {
+	if h.config.timeout <= 0 {
+		return h.fn(ctx)
+	}
+	runCtx, cancel := context.WithTimeout(ctx, h.config.timeout)
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() { errCh <- h.fn(runCtx) }()
+	select {
+	case err := <-errCh:
+		return err
+	case <-runCtx.Done():
+		return runCtx.Err()
+	}
+}
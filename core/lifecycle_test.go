@@ -0,0 +1,70 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestLayerHooksChain(t *testing.T) {
+	var mu sync.Mutex
+	var ran []string
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			ran = append(ran, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	l := NewLifecycle()
+	l.OnWarmup("a", record("a"))
+	l.OnWarmup("b", record("b"), After("a"))
+	l.OnWarmup("c", record("c"), After("b"))
+
+	if err := l.RunWarmups(context.Background()); err != nil {
+		t.Fatalf("RunWarmups: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, name := range ran {
+		counts[name]++
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if counts[name] != 1 {
+			t.Errorf("hook %q ran %d times, want exactly 1 (ran=%v)", name, counts[name], ran)
+		}
+	}
+}
+
+func TestLayerHooksCycle(t *testing.T) {
+	hooks := []*hook{
+		newHook("a", func(context.Context) error { return nil }, []HookOption{After("b")}),
+		newHook("b", func(context.Context) error { return nil }, []HookOption{After("a")}),
+	}
+
+	if _, err := layerHooks(hooks); err == nil {
+		t.Fatal("layerHooks: expected a cycle error, got nil")
+	}
+}
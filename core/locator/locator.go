@@ -0,0 +1,230 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package locator provides a typed, lazily-resolved service registry.
+//
+// It exists to replace patterns like serviceCore.Items(), where every
+// consumer has to type-assert its way out of a map[string]interface{}.
+// Constructors are registered under a string key and are not invoked until
+// the first Get for that key; the registry records which keys were
+// resolved while building which other keys, so dependency cycles between
+// constructors are caught instead of deadlocking or stack-overflowing.
+package locator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// entry holds the lazily-constructed value for a single registered key.
+type entry struct {
+	once sync.Once
+	done int32 // atomic; 1 once once.Do has returned
+	val  interface{}
+	err  error
+}
+
+// Registry is a typed service locator keyed by H, the host type that
+// constructors are invoked with (normally core.ServiceHost). A zero
+// Registry is not usable; create one with NewRegistry.
+type Registry[H any] struct {
+	mu    sync.RWMutex
+	ctors map[string]func(H) (interface{}, error)
+	types map[string]string
+	entries map[string]*entry
+
+	buildMu sync.Mutex
+	stack   []string
+	edges   map[string]map[string]struct{}
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry[H any]() *Registry[H] {
+	return &Registry[H]{
+		ctors:   make(map[string]func(H) (interface{}, error)),
+		types:   make(map[string]string),
+		entries: make(map[string]*entry),
+		edges:   make(map[string]map[string]struct{}),
+	}
+}
+
+// Register adds a constructor for key. The constructor is not invoked
+// until the first Get (or MustGet) for key. Registering the same key
+// twice replaces the constructor and discards any value already
+// resolved for it.
+func Register[T any, H any](r *Registry[H], key string, ctor func(H) (T, error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var zero T
+	r.ctors[key] = func(h H) (interface{}, error) { return ctor(h) }
+	r.types[key] = fmt.Sprintf("%T", zero)
+	r.entries[key] = &entry{}
+}
+
+// Override replaces the value for key with value directly, without ever
+// invoking a constructor. It is meant for tests that need to swap a
+// dependency out before anything resolves it; see core/servicetest.
+func Override[T any, H any](r *Registry[H], key string, value T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e := &entry{val: value}
+	atomic.StoreInt32(&e.done, 1)
+	r.entries[key] = e
+	r.types[key] = fmt.Sprintf("%T", value)
+}
+
+// Get resolves key, invoking its constructor at most once. Concurrent
+// Gets for the same key block until the first completes. If resolving
+// key requires (transitively) resolving key again, Get returns a
+// dependency cycle error instead of deadlocking.
+func Get[T any, H any](r *Registry[H], sh H, key string) (T, error) {
+	var zero T
+
+	r.mu.RLock()
+	e, ok := r.entries[key]
+	r.mu.RUnlock()
+	if !ok {
+		return zero, fmt.Errorf("locator: no constructor registered for %q", key)
+	}
+
+	if atomic.LoadInt32(&e.done) == 0 {
+		if err := r.build(sh, key, e); err != nil {
+			return zero, err
+		}
+	}
+
+	if e.err != nil {
+		return zero, e.err
+	}
+	v, ok := e.val.(T)
+	if !ok {
+		return zero, fmt.Errorf("locator: %q is %T, not %T", key, e.val, zero)
+	}
+	return v, nil
+}
+
+// MustGet is like Get but panics if resolution fails.
+func MustGet[T any, H any](r *Registry[H], sh H, key string) T {
+	v, err := Get[T](r, sh, key)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// build runs the constructor for key, recording a dependency edge from
+// whatever key is currently being built (if any) and detecting cycles
+// via the current construction stack. buildMu is held for the whole
+// call, including the constructor invocation itself, not just the
+// push/pop of r.stack: the stack is one process-wide slice, so letting
+// two unrelated top-level Gets interleave their pushes and pops around
+// an unlocked constructor call would corrupt both cycle detection and
+// the recorded edges. DI containers build their graph once at startup,
+// so fully serializing construction is not a contended path; Get's
+// atomic done check still lets resolved keys skip build (and this
+// lock) entirely.
+func (r *Registry[H]) build(sh H, key string, e *entry) error {
+	r.buildMu.Lock()
+	defer r.buildMu.Unlock()
+
+	for _, k := range r.stack {
+		if k == key {
+			cycle := append(append([]string{}, r.stack...), key)
+			return fmt.Errorf("locator: dependency cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	if len(r.stack) > 0 {
+		r.recordEdge(r.stack[len(r.stack)-1], key)
+	}
+	r.stack = append(r.stack, key)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+
+	e.once.Do(func() {
+		r.mu.RLock()
+		ctor := r.ctors[key]
+		r.mu.RUnlock()
+		if ctor == nil {
+			e.err = fmt.Errorf("locator: no constructor registered for %q", key)
+		} else {
+			e.val, e.err = ctor(sh)
+		}
+		atomic.StoreInt32(&e.done, 1)
+	})
+
+	return nil
+}
+
+func (r *Registry[H]) recordEdge(from, to string) {
+	if r.edges[from] == nil {
+		r.edges[from] = make(map[string]struct{})
+	}
+	r.edges[from][to] = struct{}{}
+}
+
+// Snapshot returns every key resolved so far, keyed the same way Get
+// would return it, but untyped — the same shape Items() on a
+// ServiceHost has always exposed. Unlike Get, it never triggers
+// construction: a key that has not been resolved yet (or whose
+// constructor failed) is simply absent, so backward-compat consumers
+// of Items() can read whatever has already been built without forcing
+// lazily-registered constructors to run early.
+func (r *Registry[H]) Snapshot() map[string]interface{} {
+	r.mu.RLock()
+	entries := make(map[string]*entry, len(r.entries))
+	for k, e := range r.entries {
+		entries[k] = e
+	}
+	r.mu.RUnlock()
+
+	out := make(map[string]interface{}, len(entries))
+	for k, e := range entries {
+		if atomic.LoadInt32(&e.done) == 1 && e.err == nil {
+			out[k] = e.val
+		}
+	}
+	return out
+}
+
+// Graph is a snapshot of the dependency edges recorded so far: key ->
+// the keys whose constructors it called Get for. It only reflects
+// constructors that have actually run; edges for never-resolved keys
+// will not appear.
+type Graph map[string][]string
+
+// Graph returns a snapshot of the dependency graph recorded while
+// resolving entries so far.
+func (r *Registry[H]) Graph() Graph {
+	r.buildMu.Lock()
+	defer r.buildMu.Unlock()
+
+	g := make(Graph, len(r.edges))
+	for from, tos := range r.edges {
+		deps := make([]string, 0, len(tos))
+		for to := range tos {
+			deps = append(deps, to)
+		}
+		g[from] = deps
+	}
+	return g
+}
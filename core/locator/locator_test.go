@@ -0,0 +1,121 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package locator
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentGetDoesNotFabricateEdges resolves two unrelated
+// top-level keys concurrently, one of which is slow, alongside a third
+// key whose constructor genuinely depends on one of them. The
+// dependency graph must only ever contain the one real edge.
+func TestConcurrentGetDoesNotFabricateEdges(t *testing.T) {
+	r := NewRegistry[struct{}]()
+
+	Register[string](r, "slow", func(struct{}) (string, error) {
+		time.Sleep(20 * time.Millisecond)
+		return "slow", nil
+	})
+	Register[string](r, "fast", func(struct{}) (string, error) {
+		return "fast", nil
+	})
+	Register[string](r, "mid", func(h struct{}) (string, error) {
+		v, err := Get[string](r, h, "fast")
+		if err != nil {
+			return "", err
+		}
+		return "mid-" + v, nil
+	})
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"slow", "mid", "fast"} {
+		key := key
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Get[string](r, struct{}{}, key); err != nil {
+				t.Errorf("Get(%q): %v", key, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	graph := r.Graph()
+	for from, tos := range graph {
+		if from != "mid" {
+			t.Errorf("Graph() has unexpected edges from %q: %v (only mid->fast is real)", from, tos)
+			continue
+		}
+		if len(tos) != 1 || tos[0] != "fast" {
+			t.Errorf("Graph()[%q] = %v, want [fast]", from, tos)
+		}
+	}
+}
+
+// TestSnapshotOnlyIncludesResolvedKeys guards the property
+// backward-compat Items() callers rely on: Snapshot must return
+// already-resolved entries without forcing construction of keys that
+// were only registered.
+func TestSnapshotOnlyIncludesResolvedKeys(t *testing.T) {
+	r := NewRegistry[struct{}]()
+
+	var built bool
+	Register[string](r, "resolved", func(struct{}) (string, error) {
+		return "value", nil
+	})
+	Register[string](r, "unresolved", func(struct{}) (string, error) {
+		built = true
+		return "should not run", nil
+	})
+
+	if _, err := Get[string](r, struct{}{}, "resolved"); err != nil {
+		t.Fatalf("Get(resolved): %v", err)
+	}
+
+	snap := r.Snapshot()
+	if got, want := snap["resolved"], "value"; got != want {
+		t.Errorf("Snapshot()[resolved] = %v, want %v", got, want)
+	}
+	if _, ok := snap["unresolved"]; ok {
+		t.Errorf("Snapshot() included unresolved key %q", "unresolved")
+	}
+	if built {
+		t.Error("Snapshot() triggered construction of an unresolved key")
+	}
+}
+
+func TestGetDetectsCycle(t *testing.T) {
+	r := NewRegistry[struct{}]()
+
+	Register[string](r, "a", func(h struct{}) (string, error) {
+		return Get[string](r, h, "b")
+	})
+	Register[string](r, "b", func(h struct{}) (string, error) {
+		return Get[string](r, h, "a")
+	})
+
+	if _, err := Get[string](r, struct{}{}, "a"); err == nil {
+		t.Fatal("Get: expected a dependency cycle error, got nil")
+	}
+}
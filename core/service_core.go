@@ -21,13 +21,18 @@
 package core
 
 import (
+	"context"
 	"sync"
 	"time"
 
 	"go.uber.org/fx/core/config"
+	"go.uber.org/fx/core/health"
+	"go.uber.org/fx/core/locator"
+	"go.uber.org/fx/core/tracing"
 	"go.uber.org/fx/core/ulog"
 
 	"github.com/uber-go/tally"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // A ServiceHost represents the hosting environment for a service instance
@@ -41,6 +46,56 @@ type ServiceHost interface {
 	Config() config.ConfigurationProvider
 	Items() map[string]interface{}
 	Logger() ulog.Log
+
+	// Locator returns the typed service registry backing Items(). Prefer
+	// locator.Register / locator.Get / locator.Override against it over
+	// Items() for anything new; Items() is kept only as a thin,
+	// backward-compatible view over whatever the locator has resolved
+	// so far.
+	Locator() *locator.Registry[ServiceHost]
+
+	// DependencyGraph returns a snapshot of the edges recorded by the
+	// typed service locator backing Items(): key -> the keys its
+	// constructor resolved while building it. See core/locator.
+	DependencyGraph() locator.Graph
+
+	// Health returns the registry that components register named
+	// healthchecks with.
+	Health() *health.Registry
+	// Healthcheck runs (or reads the cached result of) every registered
+	// healthcheck and returns the aggregated report.
+	Healthcheck(ctx context.Context) health.HealthReport
+
+	// Lifecycle returns the registry that components register warmup
+	// and shutdown hooks with.
+	Lifecycle() *Lifecycle
+
+	// Warmup runs every hook registered with Lifecycle() and transitions
+	// State() from Initializing to Running. Whatever drives the service
+	// (e.g. main) calls this once wiring is complete and before it
+	// begins serving traffic.
+	Warmup(ctx context.Context) error
+	// Shutdown transitions State() to Draining, runs every registered
+	// shutdown hook in reverse dependency order, and transitions to
+	// Stopped. Whatever is driving the service (e.g. a SIGTERM handler)
+	// calls this once it decides to stop serving traffic.
+	Shutdown(ctx context.Context) error
+
+	// Tracer returns the host's Tracer, named after the host itself,
+	// obtained from TracerProvider().
+	Tracer() trace.Tracer
+	// TracerProvider returns the TracerProvider backing Tracer(), built
+	// from the "tracing.*" config section by core/tracing. See
+	// tracing.Build.
+	TracerProvider() trace.TracerProvider
+
+	// Reload re-reads the named config scope (see
+	// config.Provider.RegisterSource) and notifies any watchers
+	// registered through Config().Watch whose path intersects what
+	// changed. Built-in "logging" and "metrics" scopes rebuild Logger()
+	// and Metrics() respectively; see core/config/httpconfig and
+	// core/config/reloadsignal for ways to trigger it operationally.
+	Reload(scope string) error
 }
 
 // A ServiceHostContainer is meant to be embedded in a LifecycleObserver
@@ -63,14 +118,30 @@ type SetContainerer interface {
 type serviceCore struct {
 	standardConfig serviceConfig
 	roles          []string
+	stateMux       sync.Mutex
 	state          ServiceState
 	configProvider config.ConfigurationProvider
 	scopeMux       sync.Mutex
 	scope          tally.Scope
 	observer       Observer
-	items          map[string]interface{}
+	logMux         sync.Mutex
 	logConfig      ulog.Configuration
 	log            ulog.Log
+
+	registryMux sync.Mutex
+	registry    *locator.Registry[ServiceHost]
+
+	healthMux sync.Mutex
+	health    *health.Registry
+
+	lifecycleMux sync.Mutex
+	lifecycle    *Lifecycle
+
+	tracerMux      sync.Mutex
+	tracerProvider trace.TracerProvider
+
+	reloadScopesOnce sync.Once
+	reloadScopesErr  error
 }
 
 var _ ServiceHost = &serviceCore{}
@@ -91,6 +162,8 @@ func (s *serviceCore) Owner() string {
 }
 
 func (s *serviceCore) State() ServiceState {
+	s.stateMux.Lock()
+	defer s.stateMux.Unlock()
 	return s.state
 }
 
@@ -98,9 +171,117 @@ func (s *serviceCore) Roles() []string {
 	return s.standardConfig.ServiceRoles
 }
 
-// What items?
+// Locator returns the typed service registry backing this host, creating
+// it on first use. Prefer locator.Get / locator.Register over Items()
+// for anything new; Items() is kept only for existing untyped consumers.
+func (s *serviceCore) Locator() *locator.Registry[ServiceHost] {
+	s.registryMux.Lock()
+	defer s.registryMux.Unlock()
+
+	if s.registry == nil {
+		s.registry = locator.NewRegistry[ServiceHost]()
+	}
+	return s.registry
+}
+
+// Items returns a thin, backward-compatible view over whatever the
+// typed locator (see Locator) has resolved so far. New code should
+// register and fetch dependencies through locator.Register/Get/Override
+// directly instead of stashing them here.
 func (s *serviceCore) Items() map[string]interface{} {
-	return s.items
+	return s.Locator().Snapshot()
+}
+
+// DependencyGraph returns the dependency edges recorded so far by the
+// typed locator backing this host.
+func (s *serviceCore) DependencyGraph() locator.Graph {
+	return s.Locator().Graph()
+}
+
+// Health returns the healthcheck registry for this host, creating it on
+// first use and backing its per-check gauges with Metrics().
+func (s *serviceCore) Health() *health.Registry {
+	s.healthMux.Lock()
+	defer s.healthMux.Unlock()
+
+	if s.health == nil {
+		s.health = health.NewRegistry(s.Metrics())
+	}
+	return s.health
+}
+
+// Healthcheck runs (or reads the cached result of) every check
+// registered through Health() and returns the aggregated report.
+func (s *serviceCore) Healthcheck(ctx context.Context) health.HealthReport {
+	return s.Health().Healthcheck(ctx)
+}
+
+// Lifecycle returns the lifecycle hook registry for this host, creating
+// it on first use.
+func (s *serviceCore) Lifecycle() *Lifecycle {
+	s.lifecycleMux.Lock()
+	defer s.lifecycleMux.Unlock()
+
+	if s.lifecycle == nil {
+		s.lifecycle = NewLifecycle()
+	}
+	return s.lifecycle
+}
+
+// Warmup runs every registered warmup hook and transitions State() from
+// Initializing to Running (or back to Initializing on failure, so a
+// retry can be attempted). Whatever starts the service should call this
+// once wiring is complete and before it begins serving traffic.
+func (s *serviceCore) Warmup(ctx context.Context) error {
+	s.setState(Warming)
+	if err := s.Lifecycle().RunWarmups(ctx); err != nil {
+		s.setState(Initializing)
+		return err
+	}
+	s.setState(Running)
+	return nil
+}
+
+// Shutdown transitions State() to Draining, runs every registered
+// shutdown hook in reverse dependency order, and transitions to
+// Stopped. Whatever is driving the service (e.g. a SIGTERM handler)
+// should call this once it decides to stop serving traffic.
+func (s *serviceCore) Shutdown(ctx context.Context) error {
+	s.setState(Draining)
+	err := s.Lifecycle().RunShutdowns(ctx)
+	s.setState(Stopped)
+	return err
+}
+
+func (s *serviceCore) setState(state ServiceState) {
+	s.stateMux.Lock()
+	s.state = state
+	s.stateMux.Unlock()
+}
+
+// TracerProvider returns the TracerProvider built from the "tracing.*"
+// section of Config(), creating it on first use like Health() and
+// Lifecycle(). It is never nil: a service that never configures a
+// "tracing" section, or whose config fails to parse, still gets a
+// working no-op provider from tracing.Build.
+func (s *serviceCore) TracerProvider() trace.TracerProvider {
+	s.tracerMux.Lock()
+	defer s.tracerMux.Unlock()
+
+	if s.tracerProvider == nil {
+		tp, err := tracing.Build(s.configProvider)
+		if err != nil {
+			tp = trace.NewNoopTracerProvider()
+		}
+		s.tracerProvider = tp
+	}
+	return s.tracerProvider
+}
+
+// Tracer returns a Tracer named after this host, obtained from
+// TracerProvider().
+func (s *serviceCore) Tracer() trace.Tracer {
+	return s.TracerProvider().Tracer(s.Name())
 }
 
 func (s *serviceCore) Metrics() tally.Scope {
@@ -126,6 +307,71 @@ func (s *serviceCore) Config() config.ConfigurationProvider {
 	return s.configProvider
 }
 
+// Reload implements ServiceHost.Reload by forwarding to the config
+// provider installed during setup. On its first call it installs the
+// built-in "logging" and "metrics" watchers, so that reloading either
+// scope rebuilds Logger() or Metrics(). It registers no reload sources:
+// a scope with no source registered on the provider still fails.
+func (s *serviceCore) Reload(scope string) error {
+	s.reloadScopesOnce.Do(func() {
+		s.reloadScopesErr = s.installBuiltinReloadScopes()
+	})
+	if s.reloadScopesErr != nil {
+		return s.reloadScopesErr
+	}
+	return s.configProvider.Reload(scope)
+}
+
+// metricsReloadConfig is the "metrics.*" shape the built-in metrics
+// reload scope reads to rebuild Metrics(). It only covers the knobs
+// Metrics() itself already bakes in (see the TODO there).
+type metricsReloadConfig struct {
+	Prefix        string        `json:"prefix"`
+	FlushInterval time.Duration `json:"flush_interval"`
+}
+
+// installBuiltinReloadScopes registers the "logging" and "metrics"
+// reload scopes described on ServiceHost.Reload: the former rebuilds
+// Logger() from the "logging.*" config section on change, the latter
+// rebuilds Metrics() from "metrics.*". Reload calls this, via
+// reloadScopesOnce, the first time it is asked to reload anything.
+func (s *serviceCore) installBuiltinReloadScopes() error {
+	if _, err := s.configProvider.Watch("logging", func(v config.Value) {
+		var cfg ulog.Configuration
+		if err := v.Populate(&cfg); err != nil {
+			return
+		}
+		log, err := cfg.Build()
+		if err != nil {
+			return
+		}
+		s.logMux.Lock()
+		s.logConfig = cfg
+		s.log = log
+		s.logMux.Unlock()
+	}); err != nil {
+		return err
+	}
+
+	_, err := s.configProvider.Watch("metrics", func(v config.Value) {
+		var cfg metricsReloadConfig
+		if err := v.Populate(&cfg); err != nil {
+			return
+		}
+		interval := cfg.FlushInterval
+		if interval <= 0 {
+			interval = time.Second
+		}
+
+		s.scopeMux.Lock()
+		s.scope = tally.NewRootScope(cfg.Prefix, nil, tally.NullStatsReporter, interval)
+		s.scopeMux.Unlock()
+	})
+	return err
+}
+
 func (s *serviceCore) Logger() ulog.Log {
+	s.logMux.Lock()
+	defer s.logMux.Unlock()
 	return s.log
 }
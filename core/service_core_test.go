@@ -0,0 +1,106 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/fx/core/config"
+)
+
+// TestStateConcurrentWithWarmup guards against the data race between
+// Warmup writing s.state and State reading it with no lock: run with
+// `go test -race` to catch a regression.
+func TestStateConcurrentWithWarmup(t *testing.T) {
+	s := &serviceCore{}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = s.Warmup(context.Background())
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = s.State()
+		}
+	}()
+
+	wg.Wait()
+	if got := s.State(); got != Running {
+		t.Errorf("State() after Warmup = %v, want %v", got, Running)
+	}
+}
+
+// TestReloadRegistersBuiltinScopes guards against the built-in
+// "metrics" reload scope never getting registered because nothing
+// called the old, uncalled installBuiltinReloadScopes: Reload must
+// register it lazily, on the first call, so reloading a "metrics"
+// source actually rebuilds Metrics().
+func TestReloadRegistersBuiltinScopes(t *testing.T) {
+	cp := config.NewProvider(nil)
+	cp.RegisterSource("metrics", func() (map[string]interface{}, error) {
+		return map[string]interface{}{"prefix": "svc", "flush_interval": 0}, nil
+	})
+	s := &serviceCore{configProvider: cp}
+	before := s.Metrics()
+
+	if err := s.Reload("metrics"); err != nil {
+		t.Fatalf("Reload(metrics): %v", err)
+	}
+
+	// Watchers are notified once the provider's coalesce window passes.
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Metrics() == before {
+		if time.Now().After(deadline) {
+			t.Fatal("Metrics() was not rebuilt after Reload(metrics)")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A scope with no registered source should still fail the normal
+	// config.Provider way, proving installBuiltinReloadScopes didn't
+	// swallow Reload's pass-through to the config provider.
+	if err := s.Reload("unregistered"); err == nil {
+		t.Fatal("Reload(unregistered): expected an error, got nil")
+	}
+}
+
+// TestTracerProviderNeverNil guards against TracerProvider returning a
+// nil interface (which panics on the first method call through it)
+// when nothing ever called the old, uncalled initTracer: it must build
+// lazily on first access, same as Health/Lifecycle/Locator.
+func TestTracerProviderNeverNil(t *testing.T) {
+	s := &serviceCore{configProvider: config.NewProvider(nil)}
+
+	if s.TracerProvider() == nil {
+		t.Fatal("TracerProvider() = nil")
+	}
+	if s.Tracer() == nil {
+		t.Fatal("Tracer() = nil")
+	}
+}
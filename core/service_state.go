@@ -0,0 +1,61 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package core
+
+// ServiceState is a point in the lifecycle of a hosted service.
+type ServiceState int
+
+const (
+	// Uninitialized is the state before the service core has been set up.
+	Uninitialized ServiceState = iota
+	// Initializing is set while modules are being wired together.
+	Initializing
+	// Warming is set while registered warmup hooks are running, after
+	// wiring but before the service accepts traffic.
+	Warming
+	// Running is set once warmup has completed successfully and the
+	// service is serving traffic.
+	Running
+	// Draining is set once shutdown has been requested and shutdown
+	// hooks are running.
+	Draining
+	// Stopped is the terminal state once shutdown hooks have completed.
+	Stopped
+)
+
+func (s ServiceState) String() string {
+	switch s {
+	case Uninitialized:
+		return "uninitialized"
+	case Initializing:
+		return "initializing"
+	case Warming:
+		return "warming"
+	case Running:
+		return "running"
+	case Draining:
+		return "draining"
+	case Stopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package servicetest lets tests build a core.ServiceHost out of only
+// the pieces they need, instead of booting a full service. Construct a
+// Host with NewHost, replace whichever accessors the code under test
+// touches with the With* methods, and pass it in anywhere a
+// core.ServiceHost is expected.
+package servicetest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/fx/core"
+	"go.uber.org/fx/core/config"
+	"go.uber.org/fx/core/health"
+	"go.uber.org/fx/core/locator"
+	"go.uber.org/fx/core/ulog"
+
+	"github.com/uber-go/tally"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Host is a minimal, in-memory core.ServiceHost meant for tests. The
+// zero value is not usable; create one with NewHost. Host is safe for
+// concurrent use.
+type Host struct {
+	mu sync.Mutex
+
+	name        string
+	description string
+	roles       []string
+	state       core.ServiceState
+	metrics     tally.Scope
+	observer    core.Observer
+	config      config.ConfigurationProvider
+	items       map[string]interface{}
+	log         ulog.Log
+
+	registry  *locator.Registry[core.ServiceHost]
+	health    *health.Registry
+	lifecycle *core.Lifecycle
+	tracer    trace.TracerProvider
+
+	calls map[string]int
+}
+
+var _ core.ServiceHost = &Host{}
+
+// NewHost creates a Host with every accessor at its zero value. Use
+// the With* methods to fill in whatever the code under test needs.
+func NewHost() *Host {
+	return &Host{
+		items: make(map[string]interface{}),
+		calls: make(map[string]int),
+	}
+}
+
+// WithLogger replaces the value Logger() returns.
+func (h *Host) WithLogger(log ulog.Log) *Host {
+	h.mu.Lock()
+	h.log = log
+	h.mu.Unlock()
+	return h
+}
+
+// WithMetrics replaces the value Metrics() returns.
+func (h *Host) WithMetrics(scope tally.Scope) *Host {
+	h.mu.Lock()
+	h.metrics = scope
+	h.mu.Unlock()
+	return h
+}
+
+// WithConfig replaces the value Config() returns.
+func (h *Host) WithConfig(cp config.ConfigurationProvider) *Host {
+	h.mu.Lock()
+	h.config = cp
+	h.mu.Unlock()
+	return h
+}
+
+// WithObserver replaces the value Observer() returns.
+func (h *Host) WithObserver(obs core.Observer) *Host {
+	h.mu.Lock()
+	h.observer = obs
+	h.mu.Unlock()
+	return h
+}
+
+// WithItem sets a single entry in the map Items() returns, without
+// requiring a full service boot to populate it.
+func (h *Host) WithItem(key string, value interface{}) *Host {
+	h.mu.Lock()
+	h.items[key] = value
+	h.mu.Unlock()
+	return h
+}
+
+// Locator returns the typed service registry backing this Host,
+// creating it on first use. Pair it with locator.Override to replace a
+// registered constructor's result before a test resolves it. Unlike
+// serviceCore's, it does not back Items(): a Host's Items() returns
+// only what WithItem set.
+func (h *Host) Locator() *locator.Registry[core.ServiceHost] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.registry == nil {
+		h.registry = locator.NewRegistry[core.ServiceHost]()
+	}
+	return h.registry
+}
+
+// Reset clears recorded call counts and any registered items, health
+// checks, and lifecycle hooks, so a single Host can be reused across
+// table-driven subtests without state leaking between them. Values
+// set through the other With* methods (everything except WithItem)
+// are left as-is.
+func (h *Host) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.calls = make(map[string]int)
+	h.items = make(map[string]interface{})
+	h.registry = nil
+	h.health = nil
+	h.lifecycle = nil
+}
+
+// Calls returns how many times each accessor has been called since
+// construction or the last Reset.
+func (h *Host) Calls() map[string]int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := make(map[string]int, len(h.calls))
+	for name, n := range h.calls {
+		out[name] = n
+	}
+	return out
+}
+
+// CallCount returns how many times the named accessor (e.g. "Logger")
+// has been called since construction or the last Reset.
+func (h *Host) CallCount(name string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.calls[name]
+}
+
+func (h *Host) recordCall(name string) {
+	h.mu.Lock()
+	h.calls[name]++
+	h.mu.Unlock()
+}
+
+// Name returns the value set by WithName, or "" if unset.
+func (h *Host) Name() string {
+	h.recordCall("Name")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.name
+}
+
+// WithName replaces the value Name() returns.
+func (h *Host) WithName(name string) *Host {
+	h.mu.Lock()
+	h.name = name
+	h.mu.Unlock()
+	return h
+}
+
+// Description returns "" unless set through WithDescription.
+func (h *Host) Description() string {
+	h.recordCall("Description")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.description
+}
+
+// WithDescription replaces the value Description() returns.
+func (h *Host) WithDescription(description string) *Host {
+	h.mu.Lock()
+	h.description = description
+	h.mu.Unlock()
+	return h
+}
+
+// Roles returns nil unless set through WithRoles.
+func (h *Host) Roles() []string {
+	h.recordCall("Roles")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.roles
+}
+
+// WithRoles replaces the value Roles() returns.
+func (h *Host) WithRoles(roles []string) *Host {
+	h.mu.Lock()
+	h.roles = roles
+	h.mu.Unlock()
+	return h
+}
+
+// State returns the value set by WithState, or the zero ServiceState
+// (Uninitialized) if unset.
+func (h *Host) State() core.ServiceState {
+	h.recordCall("State")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.state
+}
+
+// WithState replaces the value State() returns.
+func (h *Host) WithState(state core.ServiceState) *Host {
+	h.mu.Lock()
+	h.state = state
+	h.mu.Unlock()
+	return h
+}
+
+// Metrics returns the scope set by WithMetrics, or a scope backed by a
+// tally.NullStatsReporter if unset, mirroring serviceCore's default.
+func (h *Host) Metrics() tally.Scope {
+	h.recordCall("Metrics")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.metricsLocked()
+}
+
+// metricsLocked returns h.metrics, initializing it on first use. The
+// caller must hold h.mu; it exists so Health (which already holds h.mu)
+// can reuse this without recursively locking through Metrics.
+func (h *Host) metricsLocked() tally.Scope {
+	if h.metrics == nil {
+		h.metrics = tally.NewRootScope("", nil, tally.NullStatsReporter, time.Second)
+	}
+	return h.metrics
+}
+
+// Observer returns the value set by WithObserver, or nil if unset.
+func (h *Host) Observer() core.Observer {
+	h.recordCall("Observer")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.observer
+}
+
+// Config returns the provider set by WithConfig, or an empty
+// config.Provider if unset.
+func (h *Host) Config() config.ConfigurationProvider {
+	h.recordCall("Config")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.config == nil {
+		h.config = config.NewProvider(nil)
+	}
+	return h.config
+}
+
+// Items returns a snapshot of the map populated by WithItem. It is a
+// copy, not a live view: mutating it does not affect the Host, and
+// reading it is safe even if WithItem is called concurrently.
+func (h *Host) Items() map[string]interface{} {
+	h.recordCall("Items")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make(map[string]interface{}, len(h.items))
+	for k, v := range h.items {
+		out[k] = v
+	}
+	return out
+}
+
+// Logger returns the value set by WithLogger, or the zero ulog.Log if
+// unset.
+func (h *Host) Logger() ulog.Log {
+	h.recordCall("Logger")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.log
+}
+
+// DependencyGraph returns the dependency edges recorded so far by the
+// typed locator backing this Host. See Locator.
+func (h *Host) DependencyGraph() locator.Graph {
+	h.recordCall("DependencyGraph")
+	return h.Locator().Graph()
+}
+
+// Health returns the healthcheck registry for this Host, creating it on
+// first use and backing its per-check gauges with Metrics().
+func (h *Host) Health() *health.Registry {
+	h.recordCall("Health")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.health == nil {
+		h.health = health.NewRegistry(h.metricsLocked())
+	}
+	return h.health
+}
+
+// Healthcheck runs (or reads the cached result of) every check
+// registered through Health() and returns the aggregated report.
+func (h *Host) Healthcheck(ctx context.Context) health.HealthReport {
+	h.recordCall("Healthcheck")
+	return h.Health().Healthcheck(ctx)
+}
+
+// Lifecycle returns the lifecycle hook registry for this Host, creating
+// it on first use.
+func (h *Host) Lifecycle() *core.Lifecycle {
+	h.recordCall("Lifecycle")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.lifecycle == nil {
+		h.lifecycle = core.NewLifecycle()
+	}
+	return h.lifecycle
+}
+
+// WithTracerProvider replaces the value TracerProvider() (and Tracer())
+// is built from.
+func (h *Host) WithTracerProvider(tp trace.TracerProvider) *Host {
+	h.mu.Lock()
+	h.tracer = tp
+	h.mu.Unlock()
+	return h
+}
+
+// TracerProvider returns the provider set by WithTracerProvider, or a
+// no-op provider if unset.
+func (h *Host) TracerProvider() trace.TracerProvider {
+	h.recordCall("TracerProvider")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tracer == nil {
+		h.tracer = trace.NewNoopTracerProvider()
+	}
+	return h.tracer
+}
+
+// Tracer returns a Tracer named after this Host, obtained from
+// TracerProvider().
+func (h *Host) Tracer() trace.Tracer {
+	h.recordCall("Tracer")
+	return h.TracerProvider().Tracer(h.Name())
+}
+
+// Reload forwards to Config().Reload. A Host whose Config was never
+// replaced with WithConfig reloads against an empty config.Provider,
+// which has no reload sources registered and so always fails; use
+// WithConfig with a config.NewProvider wired up via RegisterSource to
+// exercise Reload in a test.
+func (h *Host) Reload(scope string) error {
+	h.recordCall("Reload")
+	return h.Config().Reload(scope)
+}
+
+// Warmup runs every hook registered with Lifecycle() and transitions
+// State() to core.Running, mirroring serviceCore's Warmup.
+func (h *Host) Warmup(ctx context.Context) error {
+	h.recordCall("Warmup")
+
+	h.mu.Lock()
+	h.state = core.Warming
+	h.mu.Unlock()
+
+	if err := h.Lifecycle().RunWarmups(ctx); err != nil {
+		h.mu.Lock()
+		h.state = core.Initializing
+		h.mu.Unlock()
+		return err
+	}
+
+	h.mu.Lock()
+	h.state = core.Running
+	h.mu.Unlock()
+	return nil
+}
+
+// Shutdown runs every hook registered with Lifecycle() in reverse
+// dependency order and transitions State() to core.Stopped, mirroring
+// serviceCore's Shutdown.
+func (h *Host) Shutdown(ctx context.Context) error {
+	h.recordCall("Shutdown")
+
+	h.mu.Lock()
+	h.state = core.Draining
+	h.mu.Unlock()
+
+	err := h.Lifecycle().RunShutdowns(ctx)
+
+	h.mu.Lock()
+	h.state = core.Stopped
+	h.mu.Unlock()
+	return err
+}
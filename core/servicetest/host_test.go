@@ -0,0 +1,92 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package servicetest
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestItemsConcurrentWithWithItem guards against the data race (and Go
+// runtime's fatal concurrent map read/write panic) from WithItem
+// mutating the backing map while Items() returned a live view of it
+// instead of a snapshot. Run with `go test -race` to catch a
+// regression.
+func TestItemsConcurrentWithWithItem(t *testing.T) {
+	h := NewHost()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.WithItem("key", i)
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range h.Items() {
+				_ = k
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestHealthDoesNotDeadlockOnMetrics guards against Health() calling
+// the public, lock-taking Metrics() while already holding h.mu.
+func TestHealthDoesNotDeadlockOnMetrics(t *testing.T) {
+	h := NewHost()
+
+	done := make(chan struct{})
+	go func() {
+		h.Health()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Health() deadlocked")
+	}
+}
+
+func TestCallsRecordsAccessorNames(t *testing.T) {
+	h := NewHost()
+	h.Name()
+	h.Name()
+	h.Logger()
+
+	if got := h.CallCount("Name"); got != 2 {
+		t.Errorf("CallCount(Name) = %d, want 2", got)
+	}
+	if got := h.CallCount("Logger"); got != 1 {
+		t.Errorf("CallCount(Logger) = %d, want 1", got)
+	}
+
+	h.Reset()
+	if got := h.CallCount("Name"); got != 0 {
+		t.Errorf("CallCount(Name) after Reset = %d, want 0", got)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"go.uber.org/fx/core/ulog"
+)
+
+// LogWithSpan returns log enriched with the trace and span IDs from
+// ctx, so traces and logs for the same request correlate
+// automatically. It adds no span event; LogEvent does that. If ctx
+// carries no active span, it returns log unchanged.
+func LogWithSpan(ctx context.Context, log ulog.Log) ulog.Log {
+	span := trace.SpanFromContext(ctx)
+	if !span.SpanContext().IsValid() {
+		return log
+	}
+
+	sc := span.SpanContext()
+	return log.With(
+		"trace_id", sc.TraceID().String(),
+		"span_id", sc.SpanID().String(),
+	)
+}
+
+// LogEvent records msg as both a span event on ctx's active span (if
+// any) and a log line, so the two stay correlated.
+func LogEvent(ctx context.Context, log ulog.Log, msg string, keyvals ...interface{}) {
+	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		span.AddEvent(msg)
+	}
+	LogWithSpan(ctx, log).Info(msg, keyvals...)
+}
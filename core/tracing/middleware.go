@@ -0,0 +1,72 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var propagator = propagation.TraceContext{}
+
+// HTTPMiddleware extracts an incoming trace context (if any) from the
+// request headers, starts a span named after the route, and injects
+// the resulting context into the request before calling next. Install
+// it as the outermost middleware of an HTTP module.
+func HTTPMiddleware(tp trace.TracerProvider, route string) func(http.Handler) http.Handler {
+	tracer := tp.Tracer("go.uber.org/fx/core/tracing")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, route)
+			defer span.End()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// UnaryServerInterceptor is the gRPC analogue of HTTPMiddleware: it
+// extracts an incoming trace context from the metadata carried by ctx,
+// starts a span named after the RPC's full method name, and invokes
+// handler with the resulting context.
+func UnaryServerInterceptor(tp trace.TracerProvider) grpc.UnaryServerInterceptor {
+	tracer := tp.Tracer("go.uber.org/fx/core/tracing")
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		carrier := make(propagation.MapCarrier)
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			for k, v := range md {
+				if len(v) > 0 {
+					carrier.Set(k, v[0])
+				}
+			}
+		}
+		ctx = propagator.Extract(ctx, carrier)
+
+		ctx, span := tracer.Start(ctx, info.FullMethod)
+		defer span.End()
+		return handler(ctx, req)
+	}
+}
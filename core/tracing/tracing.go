@@ -0,0 +1,122 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package tracing builds an OpenTelemetry TracerProvider from the
+// "tracing.*" section of a service's configuration.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.uber.org/fx/core/config"
+)
+
+// Config is the shape of the "tracing" configuration section.
+type Config struct {
+	// Sampler is one of "always", "never" or "ratio". Defaults to a
+	// parent-based always-sample policy if empty.
+	Sampler string `json:"sampler"`
+	// SamplerArg is the sampling ratio, used when Sampler is "ratio".
+	SamplerArg float64 `json:"sampler_arg"`
+	// Exporter is one of "otlp", "stdout" or "noop" (the default).
+	Exporter string `json:"exporter"`
+	// Endpoint is the collector address, used by the "otlp" exporter.
+	Endpoint string `json:"endpoint"`
+	// ResourceAttributes are attached to every span emitted by the
+	// resulting provider, e.g. {"service.version": "1.2.3"}.
+	ResourceAttributes map[string]string `json:"resource_attributes"`
+}
+
+const configKey = "tracing"
+
+// Build reads the "tracing.*" section from cp and constructs a
+// TracerProvider accordingly. An absent section, or Exporter set to
+// "noop", yields a working no-op provider rather than an error, so
+// tracing is opt-in.
+func Build(cp config.ConfigurationProvider) (trace.TracerProvider, error) {
+	var cfg Config
+	if err := cp.Get(configKey).Populate(&cfg); err != nil {
+		return nil, fmt.Errorf("tracing: reading %q config: %w", configKey, err)
+	}
+
+	exporter, err := newExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return trace.NewNoopTracerProvider(), nil
+	}
+
+	res, err := newResource(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("tracing: building resource: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(newSampler(cfg)),
+		sdktrace.WithResource(res),
+	), nil
+}
+
+func newExporter(cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "otlp":
+		return otlptracegrpc.New(context.Background(),
+			otlptracegrpc.WithEndpoint(cfg.Endpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+	case "stdout":
+		return stdouttrace.New()
+	case "", "noop":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter %q", cfg.Exporter)
+	}
+}
+
+func newSampler(cfg Config) sdktrace.Sampler {
+	switch cfg.Sampler {
+	case "always":
+		return sdktrace.AlwaysSample()
+	case "never":
+		return sdktrace.NeverSample()
+	case "ratio":
+		return sdktrace.TraceIDRatioBased(cfg.SamplerArg)
+	default:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample())
+	}
+}
+
+func newResource(cfg Config) (*resource.Resource, error) {
+	attrs := make([]attribute.KeyValue, 0, len(cfg.ResourceAttributes))
+	for k, v := range cfg.ResourceAttributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	return resource.New(context.Background(), resource.WithAttributes(attrs...))
+}
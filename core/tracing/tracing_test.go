@@ -0,0 +1,65 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tracing
+
+import (
+	"testing"
+
+	"go.uber.org/fx/core/config"
+)
+
+// TestConfigPopulatesFromProvider guards against the Config struct's
+// tags drifting out of sync with config.Value.Populate, which decodes
+// via encoding/json: a yaml-style tag binds single-word fields through
+// case-insensitive fallback matching but silently zeroes out
+// underscored fields like sampler_arg and resource_attributes.
+func TestConfigPopulatesFromProvider(t *testing.T) {
+	p := config.NewProvider(map[string]interface{}{
+		"tracing": map[string]interface{}{
+			"sampler":             "ratio",
+			"sampler_arg":         0.25,
+			"exporter":            "stdout",
+			"endpoint":            "localhost:4317",
+			"resource_attributes": map[string]interface{}{"service.version": "1.2.3"},
+		},
+	})
+
+	var cfg Config
+	if err := p.Get(configKey).Populate(&cfg); err != nil {
+		t.Fatalf("Populate: %v", err)
+	}
+
+	if cfg.Sampler != "ratio" {
+		t.Errorf("Sampler = %q, want %q", cfg.Sampler, "ratio")
+	}
+	if cfg.SamplerArg != 0.25 {
+		t.Errorf("SamplerArg = %v, want 0.25", cfg.SamplerArg)
+	}
+	if cfg.Exporter != "stdout" {
+		t.Errorf("Exporter = %q, want %q", cfg.Exporter, "stdout")
+	}
+	if cfg.Endpoint != "localhost:4317" {
+		t.Errorf("Endpoint = %q, want %q", cfg.Endpoint, "localhost:4317")
+	}
+	if got := cfg.ResourceAttributes["service.version"]; got != "1.2.3" {
+		t.Errorf("ResourceAttributes[service.version] = %q, want %q", got, "1.2.3")
+	}
+}